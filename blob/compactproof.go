@@ -0,0 +1,123 @@
+package blob
+
+import (
+	"ddrp/crypto"
+	"github.com/pkg/errors"
+	"io"
+)
+
+// zeroHashAtLevel[i] is the hash of an all-zero sector subtree i levels
+// above the sector leaves. zeroHashAtLevel[0] is the hash of an all-zero
+// Sector, not zero4kSectorHash -- the leaves of this tree are per-sector
+// hashes, not raw 4KiB subsector hashes.
+var zeroHashAtLevel [MerkleTreeHeight + 1]crypto.Hash
+
+func init() {
+	var zeroSector Sector
+	zeroHashAtLevel[0] = HashSector(zeroSector)
+	for i := 1; i <= MerkleTreeHeight; i++ {
+		zeroHashAtLevel[i] = hashLevel(zeroHashAtLevel[i-1], zeroHashAtLevel[i-1])
+	}
+}
+
+// CompactMerkleProof is a MerkleProof with each sibling replaced by a flag
+// bit: 0 means the sibling is the zero-subtree hash for that level and is
+// omitted, 1 means its 32 bytes follow in Hashes.
+type CompactMerkleProof struct {
+	Flags  uint8
+	Hashes []crypto.Hash
+}
+
+// MakeSectorProofCompact builds a CompactMerkleProof for sectorID against
+// tree, substituting a flag bit for any sibling that equals the zero-subtree
+// hash for its level.
+func MakeSectorProofCompact(tree MerkleTree, sectorID uint8) CompactMerkleProof {
+	var proof CompactMerkleProof
+	pos := sectorID
+	for i := SubsectorProofLevel; i >= 1; i-- {
+		level := tree[i]
+		var sibling crypto.Hash
+		if pos%2 == 0 {
+			sibling = level[pos+1]
+		} else {
+			sibling = level[pos-1]
+		}
+
+		bit := uint8(SubsectorProofLevel - i)
+		if sibling != zeroHashAtLevel[bit] {
+			proof.Flags |= 1 << bit
+			proof.Hashes = append(proof.Hashes, sibling)
+		}
+		pos = pos / 2
+	}
+	return proof
+}
+
+// VerifySectorProofCompact reverses MakeSectorProofCompact, substituting the
+// level's zero-subtree hash for any sibling whose flag bit is unset.
+func VerifySectorProofCompact(sector Sector, sectorID uint8, merkleRoot crypto.Hash, proof CompactMerkleProof) bool {
+	currHash := HashSector(sector)
+	pos := sectorID
+	hashIdx := 0
+	for i := 0; i < MerkleTreeHeight; i++ {
+		var proofHash crypto.Hash
+		if proof.Flags&(1<<uint(i)) != 0 {
+			if hashIdx >= len(proof.Hashes) {
+				return false
+			}
+			proofHash = proof.Hashes[hashIdx]
+			hashIdx++
+		} else {
+			proofHash = zeroHashAtLevel[i]
+		}
+
+		if pos%2 == 0 {
+			currHash = hashLevel(currHash, proofHash)
+		} else {
+			currHash = hashLevel(proofHash, currHash)
+		}
+		pos = pos / 2
+	}
+
+	if hashIdx != len(proof.Hashes) {
+		return false
+	}
+
+	return currHash == merkleRoot
+}
+
+func (m CompactMerkleProof) Encode(w io.Writer) error {
+	if _, err := w.Write([]byte{m.Flags}); err != nil {
+		return err
+	}
+	for _, h := range m.Hashes {
+		if _, err := w.Write(h.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *CompactMerkleProof) Decode(r io.Reader) error {
+	flagB := make([]byte, 1)
+	if _, err := io.ReadFull(r, flagB); err != nil {
+		return err
+	}
+	flags := flagB[0]
+
+	var hashes []crypto.Hash
+	for i := 0; i < MerkleTreeHeight; i++ {
+		if flags&(1<<uint(i)) == 0 {
+			continue
+		}
+		var h crypto.Hash
+		if err := h.Decode(r); err != nil {
+			return errors.Wrap(err, "decoding compact proof sibling")
+		}
+		hashes = append(hashes, h)
+	}
+
+	m.Flags = flags
+	m.Hashes = hashes
+	return nil
+}
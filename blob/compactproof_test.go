@@ -0,0 +1,46 @@
+package blob
+
+import (
+	"testing"
+)
+
+func TestCompactProofRoundTrip(t *testing.T) {
+	var base MerkleBase
+	var sectors [256]Sector
+	for i := range base {
+		var s Sector
+		if i == 17 {
+			s[0] = 0xAB
+		}
+		sectors[i] = s
+		base[i] = HashSector(s)
+	}
+
+	tree := MakeTreeFromBase(base)
+	root := tree.Root()
+
+	for _, id := range []uint8{0, 17, 255} {
+		proof := MakeSectorProofCompact(tree, uint8(id))
+		if !VerifySectorProofCompact(sectors[id], uint8(id), root, proof) {
+			t.Fatalf("compact proof for sector %d did not verify", id)
+		}
+	}
+
+	// A fully empty blob collapses every sibling to a flag bit.
+	var emptyBase MerkleBase
+	for i := range emptyBase {
+		emptyBase[i] = HashSector(Sector{})
+	}
+	emptyTree := MakeTreeFromBase(emptyBase)
+	emptyProof := MakeSectorProofCompact(emptyTree, 0)
+	if len(emptyProof.Hashes) != 0 {
+		t.Fatalf("expected an all-zero-neighborhood proof to carry no hashes, got %d", len(emptyProof.Hashes))
+	}
+
+	tampered := sectors[17]
+	tampered[0] = 0xFF
+	proof := MakeSectorProofCompact(tree, 17)
+	if VerifySectorProofCompact(tampered, 17, root, proof) {
+		t.Fatal("expected compact proof to fail against a tampered sector")
+	}
+}
@@ -0,0 +1,199 @@
+package blob
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"ddrp/crypto"
+	ics23 "github.com/confio/ics23/go"
+	"github.com/pkg/errors"
+	"io"
+)
+
+// ics23LeafDomain and ics23InnerDomain separate leaf and inner node preimages
+// so a leaf hash can never be replayed as an inner node's, matching the
+// CheckAgainstSpec requirement that no InnerOp.Prefix start with LeafSpec.Prefix.
+var (
+	ics23LeafDomain  = []byte{0x00}
+	ics23InnerDomain = []byte{0x01}
+)
+
+// DDRPProofSpec describes how to interpret an ICS23Tree MerkleProof as an
+// ICS23 ExistenceProof: SHA256 over a domain-separated sectorID key and raw
+// sector value for leaves, SHA256 over a domain-separated sibling concat
+// for inner nodes, fixed 32-byte children, and a tree fixed at
+// MerkleTreeHeight levels deep.
+//
+// This can't describe DDRP's real, key-less blob.MerkleRoot: hashLeaf never
+// folds sectorID into the hash, but ics23.LeafOp.Apply hard-rejects an empty
+// Key, and confio/ics23 has no BLAKE2B_256 hash op at all (only BLAKE2B_512,
+// BLAKE2S_256, SHA256, SHA512, SHA512_256, RIPEMD160, BITCOIN). So this
+// describes a separate, auxiliary commitment, built by NewICS23Tree below,
+// whose leaves salt each sector with its sectorID and whose nodes hash with
+// SHA256 throughout. A chain wanting ICS23-verifiable DDRP sectors must
+// commit to an ICS23Tree's root, not a blob.MerkleTree's.
+var DDRPProofSpec = &ics23.ProofSpec{
+	LeafSpec: &ics23.LeafOp{
+		Hash:   ics23.HashOp_SHA256,
+		Length: ics23.LengthOp_NO_PREFIX,
+		Prefix: ics23LeafDomain,
+	},
+	InnerSpec: &ics23.InnerSpec{
+		ChildOrder:      []int32{0, 1},
+		ChildSize:       32,
+		MinPrefixLength: int32(len(ics23InnerDomain)),
+		MaxPrefixLength: int32(len(ics23InnerDomain)),
+		Hash:            ics23.HashOp_SHA256,
+	},
+	MinDepth: MerkleTreeHeight,
+	MaxDepth: MerkleTreeHeight,
+}
+
+// ics23LeafHash is an ICS23Tree's leaf hash: SHA256(ics23LeafDomain ||
+// sectorID || sector), matching exactly what ics23.LeafOp.Apply computes
+// for DDRPProofSpec.LeafSpec given Key = []byte{sectorID} and Value =
+// sector[:].
+func ics23LeafHash(sectorID uint8, sector Sector) crypto.Hash {
+	h := sha256.New()
+	h.Write(ics23LeafDomain)
+	h.Write([]byte{sectorID})
+	h.Write(sector[:])
+	var out crypto.Hash
+	h.Sum(out[:0])
+	return out
+}
+
+// ics23InnerHash is an ICS23Tree's inner node hash: SHA256(ics23InnerDomain
+// || left || right), matching what ics23.InnerOp.Apply computes for a
+// DDRPProofSpec InnerOp regardless of which side carries the sibling.
+func ics23InnerHash(left crypto.Hash, right crypto.Hash) crypto.Hash {
+	h := sha256.New()
+	h.Write(ics23InnerDomain)
+	h.Write(left[:])
+	h.Write(right[:])
+	var out crypto.Hash
+	h.Sum(out[:0])
+	return out
+}
+
+// NewICS23Tree builds a MerkleTree over sectors using ics23LeafHash and
+// ics23InnerHash instead of hashLeaf/hashLevel, so its root and proofs are
+// exactly what DDRPProofSpec expects. Its root is a distinct commitment
+// from the corresponding blob.MerkleTree's.
+func NewICS23Tree(sectors [256]Sector) MerkleTree {
+	level := make([]crypto.Hash, len(sectors))
+	for i, sector := range sectors {
+		level[i] = ics23LeafHash(uint8(i), sector)
+	}
+
+	tree := [][]crypto.Hash{level}
+	for len(tree[0]) > 1 {
+		parent := make([]crypto.Hash, len(tree[0])/2)
+		for i := 0; i < len(tree[0]); i += 2 {
+			parent[i/2] = ics23InnerHash(tree[0][i], tree[0][i+1])
+		}
+		tree = append([][]crypto.Hash{parent}, tree...)
+	}
+	return tree
+}
+
+// ToICS23 converts proof, an ICS23Tree MerkleProof that sector occupies
+// sectorID against root, into an ICS23 ExistenceProof. This lets a
+// Cosmos-SDK chain or any other ICS23-aware light client verify inclusion
+// of a DDRP sector against an on-chain-committed ICS23Tree root without
+// embedding DDRP-specific verification code.
+func ToICS23(sector Sector, sectorID uint8, proof MerkleProof, root crypto.Hash) (*ics23.CommitmentProof, error) {
+	pos := sectorID
+	pRdr := bytes.NewReader(proof[:])
+	path := make([]*ics23.InnerOp, 0, MerkleTreeHeight)
+	for i := 0; i < MerkleTreeHeight; i++ {
+		var sibling crypto.Hash
+		if _, err := io.ReadFull(pRdr, sibling[:]); err != nil {
+			return nil, errors.Wrap(err, "reading proof sibling")
+		}
+
+		op := &ics23.InnerOp{Hash: ics23.HashOp_SHA256}
+		if pos%2 == 0 {
+			op.Prefix = append([]byte{}, ics23InnerDomain...)
+			op.Suffix = sibling.Bytes()
+		} else {
+			op.Prefix = append(append([]byte{}, ics23InnerDomain...), sibling.Bytes()...)
+		}
+		path = append(path, op)
+		pos = pos / 2
+	}
+
+	return &ics23.CommitmentProof{
+		Proof: &ics23.CommitmentProof_Exist{
+			Exist: &ics23.ExistenceProof{
+				Key:   []byte{sectorID},
+				Value: sector[:],
+				Leaf:  DDRPProofSpec.LeafSpec,
+				Path:  path,
+			},
+		},
+	}, nil
+}
+
+// FromICS23 extracts the sectorID and MerkleProof carried by an ICS23
+// CommitmentProof produced by ToICS23, against an ICS23Tree, and recomputes
+// the root it commits to so the caller can compare it against that tree's
+// known root. sectorID is read directly from the proof's Key.
+func FromICS23(commitment *ics23.CommitmentProof) (sectorID uint8, proof MerkleProof, root crypto.Hash, err error) {
+	exist := commitment.GetExist()
+	if exist == nil {
+		return 0, proof, root, errors.New("ics23 proof is not an existence proof")
+	}
+	if len(exist.Key) != 1 {
+		return 0, proof, root, errors.Errorf("ics23 proof key has %d bytes, want 1", len(exist.Key))
+	}
+	if len(exist.Path) != MerkleTreeHeight {
+		return 0, proof, root, errors.Errorf("ics23 proof has %d inner ops, want %d", len(exist.Path), MerkleTreeHeight)
+	}
+	sectorID = exist.Key[0]
+
+	var buf bytes.Buffer
+	for _, op := range exist.Path {
+		switch {
+		case len(op.Suffix) == 32:
+			buf.Write(op.Suffix)
+		case len(op.Prefix) == len(ics23InnerDomain)+32:
+			buf.Write(op.Prefix[len(ics23InnerDomain):])
+		default:
+			return 0, proof, root, errors.New("ics23 inner op missing a 32-byte sibling")
+		}
+	}
+	copy(proof[:], buf.Bytes())
+
+	rootBytes, err := exist.Calculate()
+	if err != nil {
+		return 0, proof, root, errors.Wrap(err, "recomputing root from ics23 proof")
+	}
+	copy(root[:], rootBytes)
+
+	return sectorID, proof, root, nil
+}
+
+// VerifyICS23TreeProof verifies that sector occupies sectorID in an
+// ICS23Tree committed to by root, the same proof format VerifySectorProof
+// checks but hashing with ics23LeafHash/ics23InnerHash instead of
+// hashLeaf/hashLevel.
+func VerifyICS23TreeProof(sector Sector, sectorID uint8, root crypto.Hash, proof MerkleProof) bool {
+	currHash := ics23LeafHash(sectorID, sector)
+	pos := sectorID
+	pRdr := bytes.NewReader(proof[:])
+	var proofHash crypto.Hash
+
+	for i := 0; i < MerkleTreeHeight; i++ {
+		if _, err := io.ReadFull(pRdr, proofHash[:]); err != nil {
+			return false
+		}
+		if pos%2 == 0 {
+			currHash = ics23InnerHash(currHash, proofHash)
+		} else {
+			currHash = ics23InnerHash(proofHash, currHash)
+		}
+		pos = pos / 2
+	}
+
+	return currHash == root
+}
@@ -0,0 +1,43 @@
+package blob
+
+import (
+	ics23 "github.com/confio/ics23/go"
+	"testing"
+)
+
+func TestICS23RoundTrip(t *testing.T) {
+	var sectors [256]Sector
+	const sectorID = 19
+	sectors[sectorID][0] = 0x42
+
+	tree := NewICS23Tree(sectors)
+	root := tree.Root()
+	proof := MakeSectorProof(tree, sectorID)
+
+	commitment, err := ToICS23(sectors[sectorID], sectorID, proof, root)
+	if err != nil {
+		t.Fatalf("ToICS23: %v", err)
+	}
+
+	if !ics23.VerifyMembership(DDRPProofSpec, root.Bytes(), commitment, []byte{sectorID}, sectors[sectorID][:]) {
+		t.Fatal("expected ics23 to verify membership against the real ICS23Tree root")
+	}
+
+	gotID, gotProof, gotRoot, err := FromICS23(commitment)
+	if err != nil {
+		t.Fatalf("FromICS23: %v", err)
+	}
+	if gotID != sectorID {
+		t.Fatalf("sectorID = %d, want %d", gotID, sectorID)
+	}
+	if gotProof != proof {
+		t.Fatal("round-tripped proof does not match the original MerkleProof")
+	}
+	if gotRoot != root {
+		t.Fatal("round-tripped root does not match the real ICS23Tree root")
+	}
+
+	if !VerifyICS23TreeProof(sectors[sectorID], sectorID, root, gotProof) {
+		t.Fatal("round-tripped proof does not verify against the real ICS23Tree root")
+	}
+}
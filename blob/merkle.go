@@ -123,15 +123,20 @@ func VerifySectorProof(sector Sector, sectorID uint8, merkleRoot crypto.Hash, pr
 	pos := sectorID
 	pRdr := bytes.NewReader(proof[:])
 	var proofHash crypto.Hash
+
+	h := getHasher()
+	defer putHasher(h)
+
 	for i := 0; i < MerkleTreeHeight; i++ {
 		_, err := io.ReadFull(pRdr, proofHash[:])
 		if err != nil {
 			return false
 		}
+		h.Reset()
 		if pos%2 == 0 {
-			currHash = hashLevel(currHash, proofHash)
+			hashLevelInto(&currHash, currHash, proofHash, h)
 		} else {
-			currHash = hashLevel(proofHash, currHash)
+			hashLevelInto(&currHash, proofHash, currHash, h)
 		}
 		pos = pos / 2
 	}
@@ -172,12 +177,17 @@ func NewMerkleTreeFromReader(r io.Reader, leafCount int, leafSize int) (MerkleTr
 	}
 
 	buf := make([]byte, leafSize)
-	var base []crypto.Hash
+	base := make([]crypto.Hash, leafCount)
+
+	h := getHasher()
+	defer putHasher(h)
+
 	for i := 0; i < leafCount; i++ {
 		if _, err := io.ReadFull(r, buf); err != nil {
 			return nil, err
 		}
-		base = append(base, hashLeaf(buf))
+		h.Reset()
+		hashLeafInto(&base[i], buf, h)
 	}
 	return newMerkleTreeFromHashedLeaves(base)
 }
@@ -190,12 +200,15 @@ func newMerkleTreeFromHashedLeaves(base []crypto.Hash) (MerkleTree, error) {
 	tree := [][]crypto.Hash{
 		base,
 	}
+
+	h := getHasher()
+	defer putHasher(h)
+
 	for len(tree[0]) > 1 {
-		var level []crypto.Hash
+		level := make([]crypto.Hash, len(tree[0])/2)
 		for i := 0; i < len(tree[0]); i += 2 {
-			left := tree[0][i]
-			right := tree[0][i+1]
-			level = append(level, hashLevel(left, right))
+			h.Reset()
+			hashLevelInto(&level[i/2], tree[0][i], tree[0][i+1], h)
 		}
 		tree = append([][]crypto.Hash{level}, tree...)
 	}
@@ -0,0 +1,150 @@
+package blob
+
+import (
+	"ddrp/crypto"
+	"github.com/pkg/errors"
+	"hash"
+	"math/bits"
+)
+
+// hasherEntry is a pending node on MerkleHasher's stack: a hash together
+// with its depth above the leaves (0 for a leaf itself).
+type hasherEntry struct {
+	hash  crypto.Hash
+	depth int
+}
+
+// MerkleHasher computes a MerkleTree root incrementally from a stream of
+// fixed-size chunks, stack-trie style: a stack of at most height+1 pending
+// hashes, combined as matching depths meet, keeps peak memory at O(log n)
+// instead of materializing every level up front.
+type MerkleHasher struct {
+	leafSize  int
+	leafCount int
+	height    int
+
+	buf     []byte
+	written int
+
+	stack []hasherEntry
+
+	baseDepth int
+	base      []crypto.Hash
+}
+
+// NewMerkleHasher returns a MerkleHasher over leafCount chunks of leafSize
+// bytes each, matching the leaf layout NewMerkleTreeFromReader would use.
+func NewMerkleHasher(leafCount int, leafSize int) (*MerkleHasher, error) {
+	if bits.OnesCount64(uint64(leafCount)) != 1 {
+		return nil, errors.New("leafCount must be a power of two")
+	}
+
+	height := bits.Len64(uint64(leafCount)) - 1
+	return &MerkleHasher{
+		leafSize:  leafSize,
+		leafCount: leafCount,
+		height:    height,
+		buf:       make([]byte, 0, leafSize),
+		baseDepth: height - SubsectorProofLevel,
+	}, nil
+}
+
+// NewBlobMerkleHasher returns a MerkleHasher sized for a full blob, matching
+// the leaf layout used by Merkleize.
+func NewBlobMerkleHasher() *MerkleHasher {
+	h, err := NewMerkleHasher(SubsectorCountBlob, SubsectorSize)
+	if err != nil {
+		// SubsectorCountBlob is a compile-time constant and always a power of two
+		panic(err)
+	}
+	return h
+}
+
+// Write hashes complete leafSize-byte chunks of p as they accumulate,
+// buffering any partial trailing chunk for the next call.
+func (h *MerkleHasher) Write(p []byte) (int, error) {
+	total := len(p)
+
+	hasher := getHasher()
+	defer putHasher(hasher)
+
+	for len(p) > 0 {
+		n := h.leafSize - len(h.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		h.buf = append(h.buf, p[:n]...)
+		p = p[n:]
+
+		if len(h.buf) < h.leafSize {
+			continue
+		}
+		if h.written >= h.leafCount {
+			return total - len(p), errors.New("merkle hasher: too many bytes written")
+		}
+
+		var leaf crypto.Hash
+		hasher.Reset()
+		hashLeafInto(&leaf, h.buf, hasher)
+		h.pushLeaf(leaf, hasher)
+		h.buf = h.buf[:0]
+		h.written++
+	}
+	return total, nil
+}
+
+// pushLeaf pushes a freshly hashed leaf onto the stack and repeatedly
+// combines it with the top entry while the two share a depth, à la a stack
+// trie. Any node produced at baseDepth is snapshotted into base, so
+// ProtocolBase can be read off once the tree is complete without having to
+// retain the full tree.
+func (h *MerkleHasher) pushLeaf(leaf crypto.Hash, hasher hash.Hash) {
+	hsh := leaf
+	depth := 0
+	h.snapshotBase(hsh, depth)
+
+	for len(h.stack) > 0 && h.stack[len(h.stack)-1].depth == depth {
+		top := h.stack[len(h.stack)-1]
+		h.stack = h.stack[:len(h.stack)-1]
+
+		var combined crypto.Hash
+		hasher.Reset()
+		hashLevelInto(&combined, top.hash, hsh, hasher)
+		hsh = combined
+		depth++
+		h.snapshotBase(hsh, depth)
+	}
+
+	h.stack = append(h.stack, hasherEntry{hash: hsh, depth: depth})
+}
+
+func (h *MerkleHasher) snapshotBase(hash crypto.Hash, depth int) {
+	if depth == h.baseDepth {
+		h.base = append(h.base, hash)
+	}
+}
+
+// Root returns the Merkle root of all chunks written so far. It panics if
+// fewer than leafCount chunks have been written.
+func (h *MerkleHasher) Root() crypto.Hash {
+	if h.written != h.leafCount {
+		panic("merkle hasher: Root called before leafCount chunks were written")
+	}
+	return h.stack[0].hash
+}
+
+// ProtocolBase returns the sector-level MerkleBase snapshotted while
+// writing, mirroring MerkleTree.ProtocolBase. It panics unless exactly
+// leafCount chunks have been written, matching Root's contract.
+func (h *MerkleHasher) ProtocolBase() MerkleBase {
+	if h.written != h.leafCount {
+		panic("merkle hasher: ProtocolBase called before leafCount chunks were written")
+	}
+
+	var out MerkleBase
+	if len(h.base) != len(out) {
+		panic("invalid tree level")
+	}
+	copy(out[:], h.base)
+	return out
+}
@@ -0,0 +1,77 @@
+package blob
+
+import (
+	"bytes"
+	"testing"
+)
+
+func randomBlob(t *testing.T, size int) []byte {
+	t.Helper()
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestMerkleHasherMatchesMerkleize(t *testing.T) {
+	data := randomBlob(t, SubsectorCountBlob*SubsectorSize)
+
+	tree, err := Merkleize(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Merkleize: %v", err)
+	}
+
+	hasher := NewBlobMerkleHasher()
+	if _, err := hasher.Write(data); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if hasher.Root() != tree.Root() {
+		t.Fatal("MerkleHasher root does not match Merkleize root")
+	}
+	if hasher.ProtocolBase() != tree.ProtocolBase() {
+		t.Fatal("MerkleHasher base does not match Merkleize base")
+	}
+}
+
+func TestMerkleHasherMatchesMerkleizePartiallyEmpty(t *testing.T) {
+	data := make([]byte, SubsectorCountBlob*SubsectorSize)
+	copy(data, randomBlob(t, SubsectorSize*3))
+
+	tree, err := Merkleize(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Merkleize: %v", err)
+	}
+
+	hasher := NewBlobMerkleHasher()
+	if _, err := hasher.Write(data[:SubsectorSize]); err != nil {
+		t.Fatalf("Write (first chunk): %v", err)
+	}
+	if _, err := hasher.Write(data[SubsectorSize:]); err != nil {
+		t.Fatalf("Write (rest): %v", err)
+	}
+
+	if hasher.Root() != tree.Root() {
+		t.Fatal("MerkleHasher root does not match Merkleize root for a mostly-empty blob")
+	}
+	if hasher.ProtocolBase() != tree.ProtocolBase() {
+		t.Fatal("MerkleHasher base does not match Merkleize base for a mostly-empty blob")
+	}
+}
+
+func TestMerkleHasherRejectsNonPowerOfTwoLeafCount(t *testing.T) {
+	if _, err := NewMerkleHasher(3, SubsectorSize); err == nil {
+		t.Fatal("expected an error for a non-power-of-two leafCount")
+	}
+}
+
+func TestMerkleHasherPanicsOnIncompleteRoot(t *testing.T) {
+	hasher := NewBlobMerkleHasher()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Root to panic before all chunks are written")
+		}
+	}()
+	hasher.Root()
+}
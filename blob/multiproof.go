@@ -0,0 +1,169 @@
+package blob
+
+import (
+	"ddrp/crypto"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"sort"
+)
+
+// MultiProof is a compact Merkle proof that a set of sectors, identified by
+// SectorIDs, are all committed to by a single blob root. Verifying the same
+// sectors independently with MerkleProof costs len(SectorIDs)*MerkleTreeHeight
+// sibling hashes even though their authentication paths share ancestors close
+// to the root; MultiProof emits each shared ancestor's sibling only once.
+type MultiProof struct {
+	SectorIDs []uint8
+	Hashes    []crypto.Hash
+}
+
+// MakeMultiProof builds a MultiProof proving that the sectors identified by
+// sectorIDs are committed to by tree's root. It marks the requested leaf
+// positions as "known" and walks the tree bottom-up: at each level, a pair
+// of positions with both sides known needs no sibling (their parent is
+// known too), a pair with exactly one side known emits the other side's
+// hash, and a pair with neither side known contributes nothing.
+func MakeMultiProof(tree MerkleTree, sectorIDs []uint8) MultiProof {
+	ids := make([]uint8, len(sectorIDs))
+	copy(ids, sectorIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	known := make([]bool, 1<<uint(MerkleTreeHeight))
+	for _, id := range ids {
+		known[id] = true
+	}
+
+	var hashes []crypto.Hash
+	for level := MerkleTreeHeight; level >= 1; level-- {
+		parentKnown := make([]bool, len(known)/2)
+		for p := range parentKnown {
+			left, right := 2*p, 2*p+1
+			switch {
+			case known[left] && known[right]:
+				parentKnown[p] = true
+			case known[left]:
+				hashes = append(hashes, tree[level][right])
+				parentKnown[p] = true
+			case known[right]:
+				hashes = append(hashes, tree[level][left])
+				parentKnown[p] = true
+			}
+		}
+		known = parentKnown
+	}
+
+	return MultiProof{
+		SectorIDs: ids,
+		Hashes:    hashes,
+	}
+}
+
+// VerifyMultiProof checks that every sector in sectors, keyed by its sector
+// ID, is committed to by merkleRoot according to proof. It reconstructs the
+// known set from proof.SectorIDs and replays the same bottom-up walk used
+// by MakeMultiProof, consuming proof.Hashes in canonical order to fill in
+// the unknown siblings, then compares the resulting root to merkleRoot.
+func VerifyMultiProof(sectors map[uint8]Sector, merkleRoot crypto.Hash, proof MultiProof) bool {
+	if len(sectors) != len(proof.SectorIDs) {
+		return false
+	}
+
+	size := 1 << uint(MerkleTreeHeight)
+	hashes := make([]crypto.Hash, size)
+	known := make([]bool, size)
+	for _, id := range proof.SectorIDs {
+		sector, ok := sectors[id]
+		if !ok {
+			return false
+		}
+		hashes[id] = HashSector(sector)
+		known[id] = true
+	}
+
+	proofIdx := 0
+	for level := MerkleTreeHeight; level >= 1; level-- {
+		parentHashes := make([]crypto.Hash, len(known)/2)
+		parentKnown := make([]bool, len(known)/2)
+		for p := range parentKnown {
+			left, right := 2*p, 2*p+1
+			switch {
+			case known[left] && known[right]:
+				parentHashes[p] = hashLevel(hashes[left], hashes[right])
+				parentKnown[p] = true
+			case known[left]:
+				if proofIdx >= len(proof.Hashes) {
+					return false
+				}
+				parentHashes[p] = hashLevel(hashes[left], proof.Hashes[proofIdx])
+				proofIdx++
+				parentKnown[p] = true
+			case known[right]:
+				if proofIdx >= len(proof.Hashes) {
+					return false
+				}
+				parentHashes[p] = hashLevel(proof.Hashes[proofIdx], hashes[right])
+				proofIdx++
+				parentKnown[p] = true
+			}
+		}
+		hashes = parentHashes
+		known = parentKnown
+	}
+
+	if proofIdx != len(proof.Hashes) || len(hashes) != 1 || !known[0] {
+		return false
+	}
+
+	return hashes[0] == merkleRoot
+}
+
+func (m MultiProof) Encode(w io.Writer) error {
+	if len(m.SectorIDs) > math.MaxUint16 || len(m.Hashes) > math.MaxUint16 {
+		return errors.New("multi proof too large to encode")
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint16(len(m.SectorIDs))); err != nil {
+		return err
+	}
+	if _, err := w.Write(m.SectorIDs); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint16(len(m.Hashes))); err != nil {
+		return err
+	}
+	for _, h := range m.Hashes {
+		if _, err := w.Write(h.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MultiProof) Decode(r io.Reader) error {
+	var idCount uint16
+	if err := binary.Read(r, binary.BigEndian, &idCount); err != nil {
+		return err
+	}
+	sectorIDs := make([]uint8, idCount)
+	if _, err := io.ReadFull(r, sectorIDs); err != nil {
+		return err
+	}
+
+	var hashCount uint16
+	if err := binary.Read(r, binary.BigEndian, &hashCount); err != nil {
+		return err
+	}
+	hashes := make([]crypto.Hash, hashCount)
+	for i := range hashes {
+		if err := hashes[i].Decode(r); err != nil {
+			return err
+		}
+	}
+
+	m.SectorIDs = sectorIDs
+	m.Hashes = hashes
+	return nil
+}
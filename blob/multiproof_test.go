@@ -0,0 +1,38 @@
+package blob
+
+import (
+	"testing"
+)
+
+func TestMultiProofRoundTrip(t *testing.T) {
+	var base MerkleBase
+	sectors := make(map[uint8]Sector)
+	for i := range base {
+		var s Sector
+		s[0] = byte(i)
+		sectors[uint8(i)] = s
+		base[i] = HashSector(s)
+	}
+
+	tree := MakeTreeFromBase(base)
+	root := tree.Root()
+
+	ids := []uint8{0, 3, 42, 128, 255}
+	proof := MakeMultiProof(tree, ids)
+
+	subset := make(map[uint8]Sector, len(ids))
+	for _, id := range ids {
+		subset[id] = sectors[id]
+	}
+
+	if !VerifyMultiProof(subset, root, proof) {
+		t.Fatal("expected multi proof to verify")
+	}
+
+	tampered := subset[ids[0]]
+	tampered[1] = 0xFF
+	subset[ids[0]] = tampered
+	if VerifyMultiProof(subset, root, proof) {
+		t.Fatal("expected multi proof to fail against a tampered sector")
+	}
+}
@@ -0,0 +1,56 @@
+package blob
+
+import (
+	"bytes"
+	"ddrp/crypto"
+	"golang.org/x/crypto/blake2b"
+	"hash"
+	"sync"
+)
+
+// hasherPool recycles blake2b-256 hash.Hash values across the hot Merkle
+// hashing paths.
+var hasherPool = sync.Pool{
+	New: func() interface{} {
+		h, err := blake2b.New256(nil)
+		if err != nil {
+			// only fails for a non-nil key of the wrong length
+			panic(err)
+		}
+		return h
+	},
+}
+
+func getHasher() hash.Hash {
+	return hasherPool.Get().(hash.Hash)
+}
+
+func putHasher(h hash.Hash) {
+	h.Reset()
+	hasherPool.Put(h)
+}
+
+// hashLeafInto is hashLeaf with the result written into dst using a
+// caller-supplied, freshly Reset hasher.
+func hashLeafInto(dst *crypto.Hash, in []byte, h hash.Hash) {
+	if bytes.Equal(zero4kSector, in) {
+		*dst = zero4kSectorHash
+		return
+	}
+
+	h.Write(in)
+	h.Sum(dst[:0])
+}
+
+// hashLevelInto is hashLevel with the result written into dst using a
+// caller-supplied, freshly Reset hasher.
+func hashLevelInto(dst *crypto.Hash, left crypto.Hash, right crypto.Hash, h hash.Hash) {
+	if precompRes, hasPrecomp := precomputes[left]; hasPrecomp && left == right {
+		*dst = precompRes
+		return
+	}
+
+	h.Write(left[:])
+	h.Write(right[:])
+	h.Sum(dst[:0])
+}
@@ -0,0 +1,162 @@
+package blob
+
+import (
+	"bytes"
+	"ddrp/crypto"
+	"github.com/pkg/errors"
+)
+
+// ErrSparseNodeNotFound is returned by a SparseKVStore when a node has no
+// stored value.
+var ErrSparseNodeNotFound = errors.New("sparse merkle tree: node not found")
+
+// SparseKVStore is the storage interface a SparseMerkleTree persists its
+// non-empty nodes through.
+type SparseKVStore interface {
+	Get(key []byte) ([]byte, error)
+	Put(key []byte, value []byte) error
+}
+
+// MemSparseKVStore is an in-memory SparseKVStore, useful for tests and for
+// ephemeral trees that don't need to survive a restart.
+type MemSparseKVStore struct {
+	data map[string][]byte
+}
+
+func NewMemSparseKVStore() *MemSparseKVStore {
+	return &MemSparseKVStore{
+		data: make(map[string][]byte),
+	}
+}
+
+func (m *MemSparseKVStore) Get(key []byte) ([]byte, error) {
+	v, ok := m.data[string(key)]
+	if !ok {
+		return nil, ErrSparseNodeNotFound
+	}
+	return v, nil
+}
+
+func (m *MemSparseKVStore) Put(key []byte, value []byte) error {
+	m.data[string(key)] = value
+	return nil
+}
+
+// SparseMerkleTree is a height-MerkleTreeHeight Merkle tree, addressed the
+// same way as MerkleTree and MerkleBase, that persists only the nodes Update
+// has written; a store miss resolves to the level's zero-subtree hash
+// instead. Update writes every node on the path it rehashes, including nodes
+// whose subtree is all zero (as when a sector is updated to all zeros), and
+// no node is ever removed from the store. Update walks only the path from the
+// changed leaf to the root, so editing one sector doesn't re-Merkleize the
+// whole tree.
+//
+// A store may be shared by several blobs' trees, so every key is namespaced
+// by blobID; pass a stable, unique identifier per blob (e.g. its name hash).
+type SparseMerkleTree struct {
+	store  SparseKVStore
+	blobID []byte
+}
+
+func NewSparseMerkleTree(store SparseKVStore, blobID []byte) *SparseMerkleTree {
+	return &SparseMerkleTree{store: store, blobID: blobID}
+}
+
+// nodeKey encodes a node's position within t's blob as blobID, then level
+// (0 = root, MerkleTreeHeight = leaves), then its big-endian index within
+// that level.
+func (t *SparseMerkleTree) nodeKey(level int, index uint32) []byte {
+	key := make([]byte, 0, len(t.blobID)+5)
+	key = append(key, t.blobID...)
+	key = append(key, byte(level), byte(index>>24), byte(index>>16), byte(index>>8), byte(index))
+	return key
+}
+
+func (t *SparseMerkleTree) nodeHash(level int, index uint32) (crypto.Hash, error) {
+	raw, err := t.store.Get(t.nodeKey(level, index))
+	if err == ErrSparseNodeNotFound {
+		return zeroHashAtLevel[MerkleTreeHeight-level], nil
+	}
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+
+	var h crypto.Hash
+	copy(h[:], raw)
+	return h, nil
+}
+
+func (t *SparseMerkleTree) putNode(level int, index uint32, h crypto.Hash) error {
+	return t.store.Put(t.nodeKey(level, index), h.Bytes())
+}
+
+// Update hashes sector with HashSector and rehashes the path from
+// sectorID's leaf to the root, same as a dense MerkleTree's leaves would.
+func (t *SparseMerkleTree) Update(sectorID uint32, sector Sector) error {
+	if sectorID >= 1<<MerkleTreeHeight {
+		return errors.Errorf("sectorID %d out of range, must be less than %d", sectorID, 1<<MerkleTreeHeight)
+	}
+
+	hash := HashSector(sector)
+	index := sectorID
+	if err := t.putNode(MerkleTreeHeight, index, hash); err != nil {
+		return errors.Wrap(err, "writing leaf node")
+	}
+
+	for level := MerkleTreeHeight; level >= 1; level-- {
+		sibling, err := t.nodeHash(level, index^1)
+		if err != nil {
+			return errors.Wrap(err, "reading sibling node")
+		}
+
+		var parent crypto.Hash
+		if index%2 == 0 {
+			parent = hashLevel(hash, sibling)
+		} else {
+			parent = hashLevel(sibling, hash)
+		}
+
+		index = index / 2
+		if err := t.putNode(level-1, index, parent); err != nil {
+			return errors.Wrap(err, "writing parent node")
+		}
+		hash = parent
+	}
+
+	return nil
+}
+
+// Root returns the tree's current root hash.
+func (t *SparseMerkleTree) Root() (crypto.Hash, error) {
+	return t.nodeHash(0, 0)
+}
+
+// Proof returns a MerkleProof for sectorID against the tree's current root.
+func (t *SparseMerkleTree) Proof(sectorID uint32) (MerkleProof, error) {
+	var proof MerkleProof
+	var buf bytes.Buffer
+	index := sectorID
+	for level := MerkleTreeHeight; level >= 1; level-- {
+		sibling, err := t.nodeHash(level, index^1)
+		if err != nil {
+			return proof, errors.Wrap(err, "reading sibling node")
+		}
+		buf.Write(sibling.Bytes())
+		index = index / 2
+	}
+	copy(proof[:], buf.Bytes())
+	return proof, nil
+}
+
+// ProtocolBase returns the tree's 256-entry MerkleBase.
+func (t *SparseMerkleTree) ProtocolBase() (MerkleBase, error) {
+	var base MerkleBase
+	for i := range base {
+		h, err := t.nodeHash(MerkleTreeHeight, uint32(i))
+		if err != nil {
+			return base, errors.Wrap(err, "reading base node")
+		}
+		base[i] = h
+	}
+	return base, nil
+}
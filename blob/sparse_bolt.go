@@ -0,0 +1,57 @@
+package blob
+
+import (
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var sparseBucket = []byte("sparse_merkle_nodes")
+
+// BoltSparseKVStore is a SparseKVStore backed by a BoltDB file, for sparse
+// trees that need to survive a restart.
+type BoltSparseKVStore struct {
+	db *bolt.DB
+}
+
+// NewBoltSparseKVStore opens (creating if necessary) a BoltDB file at path
+// and returns a SparseKVStore backed by it.
+func NewBoltSparseKVStore(path string) (*BoltSparseKVStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening bolt db")
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(sparseBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "creating sparse node bucket")
+	}
+
+	return &BoltSparseKVStore{db: db}, nil
+}
+
+func (b *BoltSparseKVStore) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(sparseBucket).Get(key)
+		if v == nil {
+			return ErrSparseNodeNotFound
+		}
+		value = append([]byte(nil), v...)
+		return nil
+	})
+	return value, err
+}
+
+func (b *BoltSparseKVStore) Put(key []byte, value []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sparseBucket).Put(key, value)
+	})
+}
+
+// Close releases the underlying BoltDB file.
+func (b *BoltSparseKVStore) Close() error {
+	return b.db.Close()
+}
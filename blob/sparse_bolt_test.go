@@ -0,0 +1,72 @@
+package blob
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBoltSparseKVStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewBoltSparseKVStore(filepath.Join(dir, "sparse.db"))
+	if err != nil {
+		t.Fatalf("NewBoltSparseKVStore: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.Get([]byte("missing")); err != ErrSparseNodeNotFound {
+		t.Fatalf("Get(missing) = %v, want ErrSparseNodeNotFound", err)
+	}
+
+	if err := store.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := store.Get([]byte("key"))
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(got) != "value" {
+		t.Fatalf("Get = %q, want %q", got, "value")
+	}
+}
+
+func TestBoltSparseKVStoreSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sparse.db")
+
+	store, err := NewBoltSparseKVStore(path)
+	if err != nil {
+		t.Fatalf("NewBoltSparseKVStore: %v", err)
+	}
+	sparse := NewSparseMerkleTree(store, []byte("blob-a"))
+	var sector Sector
+	sector[0] = 0x42
+	if err := sparse.Update(3, sector); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	wantRoot, err := sparse.Root()
+	if err != nil {
+		t.Fatalf("Root: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewBoltSparseKVStore(path)
+	if err != nil {
+		t.Fatalf("NewBoltSparseKVStore (reopen): %v", err)
+	}
+	defer reopened.Close()
+
+	gotRoot, err := NewSparseMerkleTree(reopened, []byte("blob-a")).Root()
+	if err != nil {
+		t.Fatalf("Root (reopen): %v", err)
+	}
+	if gotRoot != wantRoot {
+		t.Fatal("root did not survive a close/reopen of the bolt file")
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package blob
+
+import (
+	"testing"
+)
+
+func TestSparseMerkleTreeMatchesReferenceTree(t *testing.T) {
+	store := NewMemSparseKVStore()
+	sparse := NewSparseMerkleTree(store, []byte("blob-a"))
+
+	var base MerkleBase
+	for i := range base {
+		base[i] = HashSector(Sector{})
+	}
+
+	ids := []uint32{0, 17, 42, 255}
+	for _, id := range ids {
+		var sector Sector
+		sector[0] = byte(id + 1)
+		if err := sparse.Update(id, sector); err != nil {
+			t.Fatalf("Update(%d): %v", id, err)
+		}
+		base[id] = HashSector(sector)
+	}
+
+	refTree := MakeTreeFromBase(base)
+
+	gotRoot, err := sparse.Root()
+	if err != nil {
+		t.Fatalf("Root: %v", err)
+	}
+	if gotRoot != refTree.Root() {
+		t.Fatal("sparse tree root does not match reference tree root")
+	}
+
+	gotBase, err := sparse.ProtocolBase()
+	if err != nil {
+		t.Fatalf("ProtocolBase: %v", err)
+	}
+	if gotBase != base {
+		t.Fatal("sparse tree base does not match reference base")
+	}
+
+	for _, id := range ids {
+		gotProof, err := sparse.Proof(id)
+		if err != nil {
+			t.Fatalf("Proof(%d): %v", id, err)
+		}
+		wantProof := MakeSectorProof(refTree, uint8(id))
+		if gotProof != wantProof {
+			t.Fatalf("Proof(%d) does not match reference proof", id)
+		}
+	}
+}
+
+func TestSparseMerkleTreeRejectsOutOfRangeSectorID(t *testing.T) {
+	sparse := NewSparseMerkleTree(NewMemSparseKVStore(), []byte("blob-a"))
+	if err := sparse.Update(256, Sector{}); err == nil {
+		t.Fatal("expected an error for a sectorID past the tree's 256-sector domain")
+	}
+}
+
+func TestSparseMerkleTreeNamespacesByBlobID(t *testing.T) {
+	store := NewMemSparseKVStore()
+	treeA := NewSparseMerkleTree(store, []byte("blob-a"))
+	treeB := NewSparseMerkleTree(store, []byte("blob-b"))
+
+	var sectorA, sectorB Sector
+	sectorA[0] = 0xaa
+	sectorB[0] = 0xbb
+
+	if err := treeA.Update(5, sectorA); err != nil {
+		t.Fatalf("treeA.Update: %v", err)
+	}
+	if err := treeB.Update(5, sectorB); err != nil {
+		t.Fatalf("treeB.Update: %v", err)
+	}
+
+	rootA, err := treeA.Root()
+	if err != nil {
+		t.Fatalf("treeA.Root: %v", err)
+	}
+	rootB, err := treeB.Root()
+	if err != nil {
+		t.Fatalf("treeB.Root: %v", err)
+	}
+	if rootA == rootB {
+		t.Fatal("two blobs sharing a store collapsed to the same root")
+	}
+
+	baseA, err := treeA.ProtocolBase()
+	if err != nil {
+		t.Fatalf("treeA.ProtocolBase: %v", err)
+	}
+	if baseA[5] != HashSector(sectorA) {
+		t.Fatal("treeB's update leaked into treeA's sector 5")
+	}
+}